@@ -2,10 +2,14 @@ package main
 
 import (
 	"encoding/csv"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"math"
+	"math/rand"
 	"os"
 	"strconv"
 	"strings"
@@ -14,9 +18,21 @@ import (
 	"github.com/olekukonko/tablewriter"
 )
 
+// mlfqBoostInterval is the number of ticks between MLFQ priority boosts; zero disables
+// boosting. main sets it from the -boost value parsed by openProcessingFile, and runSimulate
+// from its own -boost flag.
+var mlfqBoostInterval int64
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		if err := runSimulate(os.Args[0], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// CLI args
-	f, closeFile, err := openProcessingFile(os.Args...)
+	f, closeFile, cfg, err := openProcessingFile(os.Args)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -28,33 +44,63 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// First-come, first-serve scheduling
-	FCFSSchedule(os.Stdout, "First-come, first-serve", processes)
-
-	//SJFSchedule(os.Stdout, "Shortest-job-first", processes)
-	//
-	SJFSchedule(os.Stdout, "Shortest-job-first", processes)
-
-	//SJFPrioritySchedule(os.Stdout, "Priority", processes)
-	//
-	SJFPrioritySchedule(os.Stdout, "Shortest-job-first with Priority", processes)
-	//RRSchedule(os.Stdout, "Round-robin", processes)
-	RRSchedule(os.Stdout, "Round-robin", processes)
+	sink := EventSink(nullSink{})
+	if cfg.Format == "ndjson" {
+		sink = ndjsonSink{w: os.Stdout}
+	}
 
+	mlfqBoostInterval = cfg.BoostInterval
+	registerBuiltins(cfg.Format, sink, cfg.Quanta, cfg.Quantum)
+	for _, name := range cfg.Algos {
+		registry[name].Run(os.Stdout, clonedProcesses(processes))
+	}
+}
 
+// cliConfig holds the CLI-configurable parameters parsed in openProcessingFile.
+type cliConfig struct {
+	Quanta        []int64
+	BoostInterval int64
+	Format        string
+	Algos         []string
+	Quantum       int64
+}
 
-	
+var validFormats = map[string]bool{"table": true, "json": true, "ndjson": true}
+
+func openProcessingFile(args []string) (*os.File, func(), cliConfig, error) {
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	quantaFlag := fs.String("quanta", "2,4,8", "comma-separated time quanta for each MLFQ priority level, highest priority first")
+	boostFlag := fs.Int64("boost", 0, "ticks between MLFQ priority boosts; 0 disables boosting")
+	formatFlag := fs.String("format", "table", "output format: table, json, or ndjson")
+	algoFlag := fs.String("algo", "all", "comma-separated schedulers to run, e.g. fcfs,rr,sjf (default: all)")
+	quantumFlag := fs.Int64("quantum", 4, "time quantum for the round-robin scheduler")
+	if err := fs.Parse(args[1:]); err != nil {
+		return nil, nil, cliConfig{}, err
+	}
+	if fs.NArg() != 1 {
+		return nil, nil, cliConfig{}, fmt.Errorf("%w: must give a scheduling file to process", ErrInvalidArgs)
+	}
+	if !validFormats[*formatFlag] {
+		return nil, nil, cliConfig{}, fmt.Errorf("%w: -format must be one of table, json, ndjson", ErrInvalidArgs)
+	}
+	if *quantumFlag <= 0 {
+		return nil, nil, cliConfig{}, fmt.Errorf("%w: -quantum must be positive", ErrInvalidArgs)
+	}
 
-}
+	quanta, err := parseQuanta(*quantaFlag)
+	if err != nil {
+		return nil, nil, cliConfig{}, err
+	}
 
-func openProcessingFile(args ...string) (*os.File, func(), error) {
-	if len(args) != 2 {
-		return nil, nil, fmt.Errorf("%w: must give a scheduling file to process", ErrInvalidArgs)
+	algos, err := parseAlgos(*algoFlag)
+	if err != nil {
+		return nil, nil, cliConfig{}, err
 	}
+
 	// Read in CSV process CSV file
-	f, err := os.Open(args[1])
+	f, err := os.Open(fs.Arg(0))
 	if err != nil {
-		return nil, nil, fmt.Errorf("%v: error opening scheduling file", err)
+		return nil, nil, cliConfig{}, fmt.Errorf("%v: error opening scheduling file", err)
 	}
 	closeFn := func() {
 		if err := f.Close(); err != nil {
@@ -62,7 +108,436 @@ func openProcessingFile(args ...string) (*os.File, func(), error) {
 		}
 	}
 
-	return f, closeFn, nil
+	return f, closeFn, cliConfig{
+		Quanta:        quanta,
+		BoostInterval: *boostFlag,
+		Format:        *formatFlag,
+		Algos:         algos,
+		Quantum:       *quantumFlag,
+	}, nil
+}
+
+// parseQuanta parses a comma-separated list of time quanta, e.g. "2,4,8".
+func parseQuanta(s string) ([]int64, error) {
+	parts := strings.Split(s, ",")
+	quanta := make([]int64, 0, len(parts))
+	for _, p := range parts {
+		q, err := strconv.ParseInt(strings.TrimSpace(p), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid -quanta value %q", err, p)
+		}
+		if q <= 0 {
+			return nil, fmt.Errorf("%w: -quanta values must be positive, got %q", ErrInvalidArgs, p)
+		}
+		quanta = append(quanta, q)
+	}
+	return quanta, nil
+}
+
+//region Scheduler registry
+
+// allAlgos lists the built-in scheduler names, in the order they run when -algo selects all
+// of them.
+var allAlgos = []string{"fcfs", "sjf", "sjf-priority", "srtf", "rr", "mlfq"}
+
+// parseAlgos parses a comma-separated -algo value into an ordered list of scheduler names.
+// "" and "all" both mean every built-in scheduler, in allAlgos order.
+func parseAlgos(s string) ([]string, error) {
+	if s == "" || s == "all" {
+		return allAlgos, nil
+	}
+
+	valid := make(map[string]bool, len(allAlgos))
+	for _, a := range allAlgos {
+		valid[a] = true
+	}
+
+	parts := strings.Split(s, ",")
+	algos := make([]string, 0, len(parts))
+	for _, p := range parts {
+		name := strings.TrimSpace(p)
+		if !valid[name] {
+			return nil, fmt.Errorf("%w: unknown -algo %q", ErrInvalidArgs, name)
+		}
+		algos = append(algos, name)
+	}
+	return algos, nil
+}
+
+// Scheduler is the interface a scheduling algorithm implements to be driven through the
+// registry instead of being called directly. This lets callers (or tests) run a single
+// algorithm without the rest of the pipeline. Register is exported, but because this is
+// package main, only code in this package can call it; other packages cannot register
+// schedulers.
+type Scheduler interface {
+	Name() string
+	Run(w io.Writer, processes []Process) ScheduleResult
+}
+
+// registry holds the schedulers registered via Register, keyed by name.
+var registry = map[string]Scheduler{}
+
+// Register adds s to the registry under name, overwriting any scheduler previously
+// registered under that name.
+func Register(name string, s Scheduler) {
+	registry[name] = s
+}
+
+// clonedProcesses returns a fresh copy of processes. SJFSchedule and RRSchedule sort their
+// input slice in place, so callers that run more than one registered Scheduler over the same
+// source slice must pass each one its own copy, or a scheduler's sort reorders the slice out
+// from under the next one.
+func clonedProcesses(processes []Process) []Process {
+	cloned := make([]Process, len(processes))
+	copy(cloned, processes)
+	return cloned
+}
+
+// fcfsScheduler, sjfScheduler, sjfPriorityScheduler, srtfScheduler, rrScheduler, and
+// mlfqScheduler adapt the package's scheduling functions to the Scheduler interface,
+// closing over the output format, event sink, and any algorithm-specific parameters
+// (RR's quantum, MLFQ's quanta) chosen for the run.
+type fcfsScheduler struct {
+	format string
+	sink   EventSink
+}
+
+func (fcfsScheduler) Name() string { return "fcfs" }
+func (s fcfsScheduler) Run(w io.Writer, processes []Process) ScheduleResult {
+	return FCFSSchedule(w, "First-come, first-serve", processes, s.format, s.sink)
+}
+
+type sjfScheduler struct {
+	format string
+	sink   EventSink
+}
+
+func (sjfScheduler) Name() string { return "sjf" }
+func (s sjfScheduler) Run(w io.Writer, processes []Process) ScheduleResult {
+	return SJFSchedule(w, "Shortest-job-first", processes, s.format, s.sink)
+}
+
+type sjfPriorityScheduler struct {
+	format string
+	sink   EventSink
+}
+
+func (sjfPriorityScheduler) Name() string { return "sjf-priority" }
+func (s sjfPriorityScheduler) Run(w io.Writer, processes []Process) ScheduleResult {
+	return SJFPrioritySchedule(w, "Shortest-job-first with Priority", processes, s.format, s.sink)
+}
+
+type srtfScheduler struct {
+	format string
+	sink   EventSink
+}
+
+func (srtfScheduler) Name() string { return "srtf" }
+func (s srtfScheduler) Run(w io.Writer, processes []Process) ScheduleResult {
+	return SRTFSchedule(w, "Shortest-remaining-time-first", processes, s.format, s.sink)
+}
+
+type rrScheduler struct {
+	format  string
+	sink    EventSink
+	quantum int64
+}
+
+func (rrScheduler) Name() string { return "rr" }
+func (s rrScheduler) Run(w io.Writer, processes []Process) ScheduleResult {
+	return RRSchedule(w, "Round-robin", processes, s.quantum, s.format, s.sink)
+}
+
+type mlfqScheduler struct {
+	format string
+	sink   EventSink
+	quanta []int64
+}
+
+func (mlfqScheduler) Name() string { return "mlfq" }
+func (s mlfqScheduler) Run(w io.Writer, processes []Process) ScheduleResult {
+	return MLFQSchedule(w, "Multi-level feedback queue", processes, s.quanta, s.format, s.sink)
+}
+
+// registerBuiltins (re-)registers all six built-in schedulers under the names in allAlgos,
+// configured with the format, sink, MLFQ quanta, and RR quantum chosen for the current run.
+func registerBuiltins(format string, sink EventSink, quanta []int64, quantum int64) {
+	Register("fcfs", fcfsScheduler{format: format, sink: sink})
+	Register("sjf", sjfScheduler{format: format, sink: sink})
+	Register("sjf-priority", sjfPriorityScheduler{format: format, sink: sink})
+	Register("srtf", srtfScheduler{format: format, sink: sink})
+	Register("rr", rrScheduler{format: format, sink: sink, quantum: quantum})
+	Register("mlfq", mlfqScheduler{format: format, sink: sink, quanta: quanta})
+}
+
+//endregion
+
+//region Workload simulation
+
+// runSimulate implements the `simulate` subcommand: it generates a synthetic workload instead
+// of reading a CSV and feeds it through the schedulers selected by -algo (all six by default)
+// so the algorithms can be compared on the same input.
+func runSimulate(prog string, args []string) error {
+	fs := flag.NewFlagSet(prog+" simulate", flag.ContinueOnError)
+	n := fs.Int("n", 20, "number of processes to generate")
+	lambda := fs.Float64("lambda", 0.5, "mean arrival rate (processes per tick)")
+	mu := fs.Float64("mu", 0.2, "mean service rate (1 / mean burst duration)")
+	maxPriority := fs.Int64("priorities", 4, "processes are assigned a uniform random priority in [0, priorities)")
+	seed := fs.Int64("seed", 1, "seed for the random number generator, for reproducible workloads")
+	format := fs.String("format", "table", "output format: table, json, or ndjson")
+	quantaFlag := fs.String("quanta", "2,4,8", "comma-separated time quanta for each MLFQ priority level")
+	boost := fs.Int64("boost", 0, "ticks between MLFQ priority boosts; 0 disables boosting")
+	dump := fs.String("dump", "", "write the generated workload to this CSV path for reproducing the run")
+	algoFlag := fs.String("algo", "all", "comma-separated schedulers to run, e.g. fcfs,rr,sjf (default: all)")
+	quantumFlag := fs.Int64("quantum", 4, "time quantum for the round-robin scheduler")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !validFormats[*format] {
+		return fmt.Errorf("%w: -format must be one of table, json, ndjson", ErrInvalidArgs)
+	}
+	if *quantumFlag <= 0 {
+		return fmt.Errorf("%w: -quantum must be positive", ErrInvalidArgs)
+	}
+	if *n < 0 {
+		return fmt.Errorf("%w: -n must not be negative", ErrInvalidArgs)
+	}
+	if *lambda <= 0 {
+		return fmt.Errorf("%w: -lambda must be positive", ErrInvalidArgs)
+	}
+	if *mu <= 0 {
+		return fmt.Errorf("%w: -mu must be positive", ErrInvalidArgs)
+	}
+	quanta, err := parseQuanta(*quantaFlag)
+	if err != nil {
+		return err
+	}
+	algos, err := parseAlgos(*algoFlag)
+	if err != nil {
+		return err
+	}
+
+	processes := generateWorkload(*n, *lambda, *mu, *maxPriority, *seed)
+
+	if *dump != "" {
+		if err := dumpProcessesCSV(*dump, processes); err != nil {
+			return err
+		}
+	}
+
+	mlfqBoostInterval = *boost
+	sink := EventSink(nullSink{})
+	if *format == "ndjson" {
+		sink = ndjsonSink{w: os.Stdout}
+	}
+
+	registerBuiltins(*format, sink, quanta, *quantumFlag)
+	results := make([]ScheduleResult, 0, len(algos))
+	for _, name := range algos {
+		results = append(results, registry[name].Run(os.Stdout, clonedProcesses(processes)))
+	}
+
+	if *format == "table" {
+		outputComparison(os.Stdout, results)
+	}
+
+	return nil
+}
+
+// generateWorkload produces n synthetic processes with Poisson arrivals and exponentially
+// distributed burst durations: inter-arrival times are -ln(U)/lambda and burst durations are
+// -ln(U)/mu, the standard inverse-CDF sampling of an exponential distribution. Arrival times
+// are the running sum rounded to whole ticks, so they never decrease; burst durations are
+// rounded and never less than 1.
+func generateWorkload(n int, lambda, mu float64, maxPriority, seed int64) []Process {
+	rng := rand.New(rand.NewSource(seed))
+
+	processes := make([]Process, n)
+	var clock float64
+	for i := 0; i < n; i++ {
+		clock += -math.Log(randUnit(rng)) / lambda
+		burst := -math.Log(randUnit(rng)) / mu
+
+		processes[i] = Process{
+			ProcessID:     int64(i + 1),
+			ArrivalTime:   int64(math.Round(clock)),
+			BurstDuration: int64(math.Max(1, math.Round(burst))),
+			Priority:      rng.Int63n(maxPriority),
+		}
+	}
+	return processes
+}
+
+// randUnit returns a sample in (0, 1], avoiding the 0 that rng.Float64() can return and that
+// would make -math.Log diverge to +Inf.
+func randUnit(rng *rand.Rand) float64 {
+	u := rng.Float64()
+	if u == 0 {
+		return 1
+	}
+	return u
+}
+
+// dumpProcessesCSV writes processes to path in the same ProcessID,Burst,Arrival,Priority column
+// order that loadProcesses reads, so a simulated workload can be replayed later.
+func dumpProcessesCSV(path string, processes []Process) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("%w: creating dump file", err)
+	}
+	defer f.Close()
+
+	cw := csv.NewWriter(f)
+	for _, p := range processes {
+		row := []string{
+			strconv.FormatInt(p.ProcessID, 10),
+			strconv.FormatInt(p.BurstDuration, 10),
+			strconv.FormatInt(p.ArrivalTime, 10),
+			strconv.FormatInt(p.Priority, 10),
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("%w: writing dump row", err)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// outputComparison prints a short per-algorithm summary so the schedulers can be compared on
+// the same simulated workload at a glance.
+func outputComparison(w io.Writer, results []ScheduleResult) {
+	_, _ = fmt.Fprintln(w, "Comparison")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Algorithm", "Average Wait", "Average Turnaround", "Throughput"})
+	for _, r := range results {
+		table.Append([]string{
+			r.Title,
+			fmt.Sprintf("%.2f", r.AverageWait),
+			fmt.Sprintf("%.2f", r.AverageTurnaround),
+			fmt.Sprintf("%.2f/t", r.Throughput),
+		})
+	}
+	table.Render()
+}
+
+//endregion
+
+// EventSink receives scheduling decisions as they happen, so the table/json renderers and the
+// ndjson event trace share one code path through the schedulers instead of each output mode
+// needing its own copy of the scheduling loop.
+type EventSink interface {
+	Dispatch(t, pid int64)
+	Preempt(t, pid int64)
+	Complete(t, pid, wait, turnaround int64)
+}
+
+// nullSink discards events; used whenever the output format isn't ndjson.
+type nullSink struct{}
+
+func (nullSink) Dispatch(int64, int64)               {}
+func (nullSink) Preempt(int64, int64)                {}
+func (nullSink) Complete(int64, int64, int64, int64) {}
+
+// ndjsonSink streams one JSON object per scheduling decision to w.
+type ndjsonSink struct {
+	w io.Writer
+}
+
+type ndjsonEvent struct {
+	T          int64  `json:"t"`
+	Event      string `json:"event"`
+	PID        int64  `json:"pid"`
+	Wait       int64  `json:"wait,omitempty"`
+	Turnaround int64  `json:"turnaround,omitempty"`
+}
+
+func (s ndjsonSink) Dispatch(t, pid int64) {
+	s.emit(ndjsonEvent{T: t, Event: "dispatch", PID: pid})
+}
+
+func (s ndjsonSink) Preempt(t, pid int64) {
+	s.emit(ndjsonEvent{T: t, Event: "preempt", PID: pid})
+}
+
+func (s ndjsonSink) Complete(t, pid, wait, turnaround int64) {
+	s.emit(ndjsonEvent{T: t, Event: "complete", PID: pid, Wait: wait, Turnaround: turnaround})
+}
+
+func (s ndjsonSink) emit(e ndjsonEvent) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintln(s.w, string(b))
+}
+
+// ProcessResult is the typed, per-process row of a schedule result. Queue is only meaningful
+// for MLFQSchedule.
+type ProcessResult struct {
+	ProcessID     int64 `json:"process_id"`
+	Priority      int64 `json:"priority"`
+	BurstDuration int64 `json:"burst_duration"`
+	ArrivalTime   int64 `json:"arrival_time"`
+	Wait          int64 `json:"wait"`
+	Turnaround    int64 `json:"turnaround"`
+	Exit          int64 `json:"exit"`
+	Queue         int   `json:"queue,omitempty"`
+}
+
+// ScheduleResult is the full, typed result of a scheduler run, used for both the table and the
+// json output formats.
+type ScheduleResult struct {
+	Title             string          `json:"title"`
+	Gantt             []TimeSlice     `json:"gantt"`
+	Processes         []ProcessResult `json:"processes"`
+	AverageWait       float64         `json:"average_wait"`
+	AverageTurnaround float64         `json:"average_turnaround"`
+	Throughput        float64         `json:"throughput"`
+}
+
+// renderSchedule writes result in the requested format. In ndjson mode it does nothing further,
+// since the scheduling decisions were already streamed through the EventSink.
+func renderSchedule(w io.Writer, format string, result ScheduleResult, latency latencyDigests, mlfq bool) {
+	switch format {
+	case "ndjson":
+		// Already emitted via EventSink.
+	case "json":
+		b, err := json.Marshal(result)
+		if err != nil {
+			log.Fatal(err)
+		}
+		_, _ = fmt.Fprintln(w, string(b))
+	default:
+		rows := formatRows(result.Processes, mlfq)
+		outputTitle(w, result.Title)
+		outputGantt(w, result.Gantt)
+		if mlfq {
+			outputMLFQSchedule(w, rows, result.AverageWait, result.AverageTurnaround, result.Throughput, latency)
+		} else {
+			outputSchedule(w, rows, result.AverageWait, result.AverageTurnaround, result.Throughput, latency)
+		}
+	}
+}
+
+// formatRows converts typed ProcessResults into the [][]string rows the table renderer expects.
+func formatRows(results []ProcessResult, mlfq bool) [][]string {
+	rows := make([][]string, len(results))
+	for i, r := range results {
+		row := []string{
+			fmt.Sprint(r.ProcessID),
+			fmt.Sprint(r.Priority),
+			fmt.Sprint(r.BurstDuration),
+			fmt.Sprint(r.ArrivalTime),
+			fmt.Sprint(r.Wait),
+			fmt.Sprint(r.Turnaround),
+			fmt.Sprint(r.Exit),
+		}
+		if mlfq {
+			row = append(row, fmt.Sprint(r.Queue))
+		}
+		rows[i] = row
+	}
+	return rows
 }
 
 type (
@@ -93,15 +568,17 @@ type (
 // • an output writer
 // • a title for the chart
 // • a slice of processes
-func FCFSSchedule(w io.Writer, title string, processes []Process) {
+func FCFSSchedule(w io.Writer, title string, processes []Process, format string, sink EventSink) ScheduleResult {
 	var (
 		serviceTime     int64
 		totalWait       float64
 		totalTurnaround float64
 		lastCompletion  float64
 		waitingTime     int64
-		schedule        = make([][]string, len(processes))
+		results         = make([]ProcessResult, len(processes))
 		gantt           = make([]TimeSlice, 0)
+		waits           = make([]float64, len(processes))
+		turnarounds     = make([]float64, len(processes))
 	)
 	for i := range processes {
 		if processes[i].ArrivalTime > 0 {
@@ -117,14 +594,14 @@ func FCFSSchedule(w io.Writer, title string, processes []Process) {
 		completion := processes[i].BurstDuration + processes[i].ArrivalTime + waitingTime
 		lastCompletion = float64(completion)
 
-		schedule[i] = []string{
-			fmt.Sprint(processes[i].ProcessID),
-			fmt.Sprint(processes[i].Priority),
-			fmt.Sprint(processes[i].BurstDuration),
-			fmt.Sprint(processes[i].ArrivalTime),
-			fmt.Sprint(waitingTime),
-			fmt.Sprint(turnaround),
-			fmt.Sprint(completion),
+		results[i] = ProcessResult{
+			ProcessID:     processes[i].ProcessID,
+			Priority:      processes[i].Priority,
+			BurstDuration: processes[i].BurstDuration,
+			ArrivalTime:   processes[i].ArrivalTime,
+			Wait:          waitingTime,
+			Turnaround:    turnaround,
+			Exit:          completion,
 		}
 		serviceTime += processes[i].BurstDuration
 
@@ -133,6 +610,13 @@ func FCFSSchedule(w io.Writer, title string, processes []Process) {
 			Start: start,
 			Stop:  serviceTime,
 		})
+
+		sink.Dispatch(start, processes[i].ProcessID)
+		sink.Complete(completion, processes[i].ProcessID, waitingTime, turnaround)
+
+		// FCFS never preempts, so a process's first (and only) response is its wait time.
+		waits[i] = float64(waitingTime)
+		turnarounds[i] = float64(turnaround)
 	}
 
 	count := float64(len(processes))
@@ -140,14 +624,21 @@ func FCFSSchedule(w io.Writer, title string, processes []Process) {
 	aveTurnaround := totalTurnaround / count
 	aveThroughput := count / lastCompletion
 
-	outputTitle(w, title)
-	outputGantt(w, gantt)
-	outputSchedule(w, schedule, aveWait, aveTurnaround, aveThroughput)
+	result := ScheduleResult{
+		Title:             title,
+		Gantt:             gantt,
+		Processes:         results,
+		AverageWait:       aveWait,
+		AverageTurnaround: aveTurnaround,
+		Throughput:        aveThroughput,
+	}
+	renderSchedule(w, format, result, newLatencyDigests(waits, turnarounds, waits), false)
+	return result
 }
 
 //func SJFPrioritySchedule(w io.Writer, title string, processes []Process) { }
 //
-func SJFPrioritySchedule(w io.Writer, title string, processes []Process) {
+func SJFPrioritySchedule(w io.Writer, title string, processes []Process, format string, sink EventSink) ScheduleResult {
     var currentTime int64
     var completedProcesses int = 0
     var isRunning bool = false
@@ -181,6 +672,7 @@ func SJFPrioritySchedule(w io.Writer, title string, processes []Process) {
                     }
                 }
                 gantt = append(gantt, TimeSlice{PID: currentProcess.ProcessID, Start: currentTime - currentProcess.BurstDuration, Stop: currentTime})
+                sink.Complete(currentTime, currentProcess.ProcessID, currentTime-currentProcess.ArrivalTime-currentProcess.BurstDuration, currentTime-currentProcess.ArrivalTime)
             }
         }
 
@@ -197,6 +689,7 @@ func SJFPrioritySchedule(w io.Writer, title string, processes []Process) {
             remainingTime = currentProcess.BurstDuration
             isRunning = true
             queue = queue[1:] // Dequeue the current process
+            sink.Dispatch(currentTime, currentProcess.ProcessID)
         } else if isRunning {
             remainingTime--
         }
@@ -216,24 +709,37 @@ func SJFPrioritySchedule(w io.Writer, title string, processes []Process) {
     aveTurnaround := float64(totalTurnaroundTime) / float64(len(processes))
     aveThroughput := float64(len(processes)) / float64(currentTime)
 
-    // Convert originalProcesses to [][]string format for output
-    rows := make([][]string, len(originalProcesses))
+    // Convert originalProcesses to typed results for output
+    results := make([]ProcessResult, len(originalProcesses))
+    waits := make([]float64, len(originalProcesses))
+    turnarounds := make([]float64, len(originalProcesses))
     for i, p := range originalProcesses {
-        rows[i] = []string{
-            fmt.Sprint(p.ProcessID),
-            fmt.Sprint(p.Priority),
-            fmt.Sprint(p.BurstDuration),
-            fmt.Sprint(p.ArrivalTime),
-            fmt.Sprint(p.Exit - p.ArrivalTime - p.BurstDuration), // Wait time
-            fmt.Sprint(p.Exit - p.ArrivalTime),                   // Turnaround time
-            fmt.Sprint(p.Exit),
+        wait := p.Exit - p.ArrivalTime - p.BurstDuration
+        turnaround := p.Exit - p.ArrivalTime
+        results[i] = ProcessResult{
+            ProcessID:     p.ProcessID,
+            Priority:      p.Priority,
+            BurstDuration: p.BurstDuration,
+            ArrivalTime:   p.ArrivalTime,
+            Wait:          wait,
+            Turnaround:    turnaround,
+            Exit:          p.Exit,
         }
+        waits[i] = float64(wait)
+        turnarounds[i] = float64(turnaround)
     }
 
     // Output results
-    outputTitle(w, title)
-    outputGantt(w, gantt)
-    outputSchedule(w, rows, aveWait, aveTurnaround, aveThroughput)
+    result := ScheduleResult{
+        Title:             title,
+        Gantt:             gantt,
+        Processes:         results,
+        AverageWait:       aveWait,
+        AverageTurnaround: aveTurnaround,
+        Throughput:        aveThroughput,
+    }
+    renderSchedule(w, format, result, newLatencyDigests(waits, turnarounds, waits), false)
+    return result
 }
 
 
@@ -241,7 +747,7 @@ func SJFPrioritySchedule(w io.Writer, title string, processes []Process) {
 
 //func SJFSchedule(w io.Writer, title string, processes []Process) { }
 //
-func SJFSchedule(w io.Writer, title string, processes []Process) {
+func SJFSchedule(w io.Writer, title string, processes []Process, format string, sink EventSink) ScheduleResult {
     sort.SliceStable(processes, func(i, j int) bool {
         return processes[i].BurstDuration < processes[j].BurstDuration
     })
@@ -251,7 +757,9 @@ func SJFSchedule(w io.Writer, title string, processes []Process) {
         totalWait        float64
         totalTurnaround  float64
         gantt            = make([]TimeSlice, 0)
-        schedule         = make([][]string, len(processes))
+        results          = make([]ProcessResult, len(processes))
+        waits            = make([]float64, len(processes))
+        turnarounds      = make([]float64, len(processes))
     )
 
     for i := range processes {
@@ -269,14 +777,14 @@ func SJFSchedule(w io.Writer, title string, processes []Process) {
 
         completion := currentTime + processes[i].BurstDuration
 
-        schedule[i] = []string{
-            fmt.Sprint(processes[i].ProcessID),
-            fmt.Sprint(processes[i].Priority),
-            fmt.Sprint(processes[i].BurstDuration),
-            fmt.Sprint(processes[i].ArrivalTime),
-            fmt.Sprint(waitingTime),
-            fmt.Sprint(turnaround),
-            fmt.Sprint(completion),
+        results[i] = ProcessResult{
+            ProcessID:     processes[i].ProcessID,
+            Priority:      processes[i].Priority,
+            BurstDuration: processes[i].BurstDuration,
+            ArrivalTime:   processes[i].ArrivalTime,
+            Wait:          waitingTime,
+            Turnaround:    turnaround,
+            Exit:          completion,
         }
 
         gantt = append(gantt, TimeSlice{
@@ -285,6 +793,12 @@ func SJFSchedule(w io.Writer, title string, processes []Process) {
             Stop:  completion,
         })
 
+        sink.Dispatch(start, processes[i].ProcessID)
+        sink.Complete(completion, processes[i].ProcessID, waitingTime, turnaround)
+
+        waits[i] = float64(waitingTime)
+        turnarounds[i] = float64(turnaround)
+
         currentTime = completion
     }
 
@@ -293,16 +807,181 @@ func SJFSchedule(w io.Writer, title string, processes []Process) {
     aveTurnaround := totalTurnaround / count
     aveThroughput := count / float64(currentTime)
 
-    outputTitle(w, title)
-    outputGantt(w, gantt)
-    outputSchedule(w, schedule, aveWait, aveTurnaround, aveThroughput)
+    result := ScheduleResult{
+        Title:             title,
+        Gantt:             gantt,
+        Processes:         results,
+        AverageWait:       aveWait,
+        AverageTurnaround: aveTurnaround,
+        Throughput:        aveThroughput,
+    }
+    renderSchedule(w, format, result, newLatencyDigests(waits, turnarounds, waits), false)
+    return result
 }
 
 
+// SRTFSchedule outputs a schedule of processes in a GANTT chart and a table of timing given:
+// • an output writer
+// • a title for the chart
+// • a slice of processes
+//
+// Unlike SJFSchedule, SRTFSchedule is preemptive (Shortest Remaining Time First): at every
+// tick it admits arrivals, then runs whichever ready process has the smallest remaining burst,
+// breaking ties by Priority then ProcessID. A running process is preempted the instant another
+// ready process comes first in that order: a strictly shorter remaining burst, or an equal one
+// with a lower Priority (or, at equal Priority, a lower ProcessID). The input slice is left
+// untouched.
+func SRTFSchedule(w io.Writer, title string, processes []Process, format string, sink EventSink) ScheduleResult {
+	procs := make([]Process, len(processes))
+	copy(procs, processes)
+
+	remaining := make(map[int64]int64, len(procs))
+	arrival := make(map[int64]int64, len(procs))
+	burst := make(map[int64]int64, len(procs))
+	exit := make(map[int64]int64, len(procs))
+	firstStart := make(map[int64]int64, len(procs))
+	for i := range procs {
+		remaining[procs[i].ProcessID] = procs[i].BurstDuration
+		arrival[procs[i].ProcessID] = procs[i].ArrivalTime
+		burst[procs[i].ProcessID] = procs[i].BurstDuration
+	}
+
+	var (
+		clock      int64
+		completed  int
+		currentPID int64 = -1
+		sliceStart int64
+		gantt      = make([]TimeSlice, 0)
+	)
+
+	for completed < len(procs) {
+		best := -1
+		for i := range procs {
+			if procs[i].ArrivalTime > clock || remaining[procs[i].ProcessID] == 0 {
+				continue
+			}
+			if best == -1 || shorterRemaining(procs[i], procs[best], remaining) {
+				best = i
+			}
+		}
+
+		if best == -1 {
+			if currentPID != -1 {
+				gantt = append(gantt, TimeSlice{PID: currentPID, Start: sliceStart, Stop: clock})
+				currentPID = -1
+			}
+			clock++
+			continue
+		}
+
+		pid := procs[best].ProcessID
+		if _, seen := firstStart[pid]; !seen {
+			firstStart[pid] = clock
+		}
+		if pid != currentPID {
+			if currentPID != -1 {
+				gantt = append(gantt, TimeSlice{PID: currentPID, Start: sliceStart, Stop: clock})
+				if remaining[currentPID] > 0 {
+					sink.Preempt(clock, currentPID)
+				}
+			}
+			currentPID = pid
+			sliceStart = clock
+			sink.Dispatch(clock, pid)
+		}
+
+		remaining[pid]--
+		clock++
+
+		if remaining[pid] == 0 {
+			exit[pid] = clock
+			completed++
+			turnaround := clock - arrival[pid]
+			sink.Complete(clock, pid, turnaround-burst[pid], turnaround)
+		}
+	}
+	if currentPID != -1 {
+		gantt = append(gantt, TimeSlice{PID: currentPID, Start: sliceStart, Stop: clock})
+	}
+	gantt = coalesceGantt(gantt)
+
+	var (
+		totalWait       float64
+		totalTurnaround float64
+		results         = make([]ProcessResult, len(procs))
+		waits           = make([]float64, len(procs))
+		turnarounds     = make([]float64, len(procs))
+		responses       = make([]float64, len(procs))
+	)
+
+	for i := range procs {
+		turnaround := exit[procs[i].ProcessID] - procs[i].ArrivalTime
+		wait := turnaround - procs[i].BurstDuration
+		totalWait += float64(wait)
+		totalTurnaround += float64(turnaround)
+
+		results[i] = ProcessResult{
+			ProcessID:     procs[i].ProcessID,
+			Priority:      procs[i].Priority,
+			BurstDuration: procs[i].BurstDuration,
+			ArrivalTime:   procs[i].ArrivalTime,
+			Wait:          wait,
+			Turnaround:    turnaround,
+			Exit:          exit[procs[i].ProcessID],
+		}
+
+		waits[i] = float64(wait)
+		turnarounds[i] = float64(turnaround)
+		responses[i] = float64(firstStart[procs[i].ProcessID] - procs[i].ArrivalTime)
+	}
+
+	count := float64(len(procs))
+	aveWait := totalWait / count
+	aveTurnaround := totalTurnaround / count
+	aveThroughput := count / float64(clock)
+
+	result := ScheduleResult{
+		Title:             title,
+		Gantt:             gantt,
+		Processes:         results,
+		AverageWait:       aveWait,
+		AverageTurnaround: aveTurnaround,
+		Throughput:        aveThroughput,
+	}
+	renderSchedule(w, format, result, newLatencyDigests(waits, turnarounds, responses), false)
+	return result
+}
+
+// shorterRemaining reports whether candidate should be preferred over current: smaller
+// remaining burst first, then lower Priority, then lower ProcessID.
+func shorterRemaining(candidate, current Process, remaining map[int64]int64) bool {
+	rc, rk := remaining[candidate.ProcessID], remaining[current.ProcessID]
+	if rc != rk {
+		return rc < rk
+	}
+	if candidate.Priority != current.Priority {
+		return candidate.Priority < current.Priority
+	}
+	return candidate.ProcessID < current.ProcessID
+}
+
+// coalesceGantt merges adjacent slices that share a PID, which can occur when a process
+// resumes immediately after another completes or is preempted and regains the CPU right away.
+func coalesceGantt(gantt []TimeSlice) []TimeSlice {
+	merged := make([]TimeSlice, 0, len(gantt))
+	for _, ts := range gantt {
+		if n := len(merged); n > 0 && merged[n-1].PID == ts.PID && merged[n-1].Stop == ts.Start {
+			merged[n-1].Stop = ts.Stop
+			continue
+		}
+		merged = append(merged, ts)
+	}
+	return merged
+}
+
 //func RRSchedule(w io.Writer, title string, processes []Process) { }
-func RRSchedule(w io.Writer, title string, processes []Process) {
+func RRSchedule(w io.Writer, title string, processes []Process, quantum int64, format string, sink EventSink) ScheduleResult {
     var clock int64 = 0
-    quantum := int64(4) // Making quantum an int64 for consistent types
     var completed int64 = 0
 
     // Sort processes based on ArrivalTime
@@ -314,6 +993,7 @@ func RRSchedule(w io.Writer, title string, processes []Process) {
     var gantt []TimeSlice
     var schedule []Process
     originalProcesses := make(map[int64]Process)
+    firstStart := make(map[int64]int64)
 
     for _, p := range processes {
         originalProcesses[p.ProcessID] = p
@@ -338,6 +1018,11 @@ func RRSchedule(w io.Writer, title string, processes []Process) {
         currentProcess := queue[0]
         queue = queue[1:]
 
+        if _, seen := firstStart[currentProcess.ProcessID]; !seen {
+            firstStart[currentProcess.ProcessID] = clock
+        }
+        sink.Dispatch(clock, currentProcess.ProcessID)
+
         timeSlice := TimeSlice{
             PID:   currentProcess.ProcessID,
             Start: clock,
@@ -348,6 +1033,7 @@ func RRSchedule(w io.Writer, title string, processes []Process) {
             clock += quantum
             currentProcess.BurstDuration -= quantum
             queue = append(queue, currentProcess)
+            sink.Preempt(clock, currentProcess.ProcessID)
         } else {
             timeSlice.Stop = clock + currentProcess.BurstDuration
             clock += currentProcess.BurstDuration
@@ -357,6 +1043,7 @@ func RRSchedule(w io.Writer, title string, processes []Process) {
             currentProcess.Wait = currentProcess.Turnaround - original.BurstDuration
             schedule = append(schedule, currentProcess)
             completed++
+            sink.Complete(clock, currentProcess.ProcessID, currentProcess.Wait, currentProcess.Turnaround)
         }
 
         gantt = append(gantt, timeSlice)
@@ -374,25 +1061,313 @@ func RRSchedule(w io.Writer, title string, processes []Process) {
     aveTurnaround := float64(totalTurnaround) / float64(completed)
     aveThroughput := float64(completed) / float64(clock)
 
-    // Convert schedule to [][]string format for output
-    rows := make([][]string, 0, len(schedule))
+    // Convert schedule to typed results for output
+    results := make([]ProcessResult, 0, len(schedule))
+    waits := make([]float64, 0, len(schedule))
+    turnarounds := make([]float64, 0, len(schedule))
+    responses := make([]float64, 0, len(schedule))
     for _, proc := range schedule {
-        row := []string{
-            strconv.FormatInt(proc.ProcessID, 10),
-            strconv.FormatInt(proc.Priority, 10),
-            strconv.FormatInt(originalProcesses[proc.ProcessID].BurstDuration, 10), // Original burst time
-            strconv.FormatInt(proc.ArrivalTime, 10),
-            strconv.FormatInt(proc.Wait, 10),
-            strconv.FormatInt(proc.Turnaround, 10),
-            strconv.FormatInt(proc.Exit, 10),
-        }
-        rows = append(rows, row)
+        results = append(results, ProcessResult{
+            ProcessID:     proc.ProcessID,
+            Priority:      proc.Priority,
+            BurstDuration: originalProcesses[proc.ProcessID].BurstDuration, // Original burst time
+            ArrivalTime:   proc.ArrivalTime,
+            Wait:          proc.Wait,
+            Turnaround:    proc.Turnaround,
+            Exit:          proc.Exit,
+        })
+
+        waits = append(waits, float64(proc.Wait))
+        turnarounds = append(turnarounds, float64(proc.Turnaround))
+        responses = append(responses, float64(firstStart[proc.ProcessID]-proc.ArrivalTime))
     }
 
     // Output
-    outputTitle(w, title)
-    outputGantt(w, gantt)
-    outputSchedule(w, rows, aveWait, aveTurnaround, aveThroughput)
+    result := ScheduleResult{
+        Title:             title,
+        Gantt:             gantt,
+        Processes:         results,
+        AverageWait:       aveWait,
+        AverageTurnaround: aveTurnaround,
+        Throughput:        aveThroughput,
+    }
+    renderSchedule(w, format, result, newLatencyDigests(waits, turnarounds, responses), false)
+    return result
+}
+
+// MLFQSchedule outputs a schedule of processes in a GANTT chart and a table of timing given:
+// • an output writer
+// • a title for the chart
+// • a slice of processes
+// • quanta, the time quantum for each priority level (index 0 is the highest priority)
+//
+// New arrivals enter the top queue. A process that uses its full quantum is demoted to the
+// next queue; a process that completes before its quantum expires is simply done. If
+// mlfqBoostInterval is non-zero, every mlfqBoostInterval ticks all processes are promoted back
+// to the top queue to prevent starvation.
+func MLFQSchedule(w io.Writer, title string, processes []Process, quanta []int64, format string, sink EventSink) ScheduleResult {
+	if len(quanta) == 0 {
+		quanta = []int64{2, 4, 8}
+	}
+
+	procs := make([]Process, len(processes))
+	copy(procs, processes)
+	sort.SliceStable(procs, func(i, j int) bool {
+		return procs[i].ArrivalTime < procs[j].ArrivalTime
+	})
+
+	remaining := make(map[int64]int64, len(procs))
+	finalLevel := make(map[int64]int, len(procs))
+	exit := make(map[int64]int64, len(procs))
+	firstStart := make(map[int64]int64, len(procs))
+	for i := range procs {
+		remaining[procs[i].ProcessID] = procs[i].BurstDuration
+	}
+
+	pending := procs
+	queues := make([][]Process, len(quanta))
+	gantt := make([]TimeSlice, 0)
+
+	var clock int64
+	var completed int
+	nextBoost := mlfqBoostInterval
+
+	admitArrivals := func() {
+		for len(pending) > 0 && pending[0].ArrivalTime <= clock {
+			queues[0] = append(queues[0], pending[0])
+			pending = pending[1:]
+		}
+	}
+
+	for completed < len(procs) {
+		admitArrivals()
+
+		// clock advances in variable-size jumps (a level's quantum, or less if a process
+		// finishes early), so a boost can land strictly between two ticks; checking that the
+		// clock has crossed nextBoost catches that where an equality check would not.
+		for mlfqBoostInterval > 0 && clock >= nextBoost {
+			for l := 1; l < len(queues); l++ {
+				queues[0] = append(queues[0], queues[l]...)
+				queues[l] = nil
+			}
+			nextBoost += mlfqBoostInterval
+		}
+
+		level := -1
+		for i := range queues {
+			if len(queues[i]) > 0 {
+				level = i
+				break
+			}
+		}
+		if level == -1 {
+			clock++
+			continue
+		}
+
+		p := queues[level][0]
+		queues[level] = queues[level][1:]
+
+		if _, seen := firstStart[p.ProcessID]; !seen {
+			firstStart[p.ProcessID] = clock
+		}
+		sink.Dispatch(clock, p.ProcessID)
+
+		run := quanta[level]
+		if remaining[p.ProcessID] < run {
+			run = remaining[p.ProcessID]
+		}
+
+		start := clock
+		clock += run
+		remaining[p.ProcessID] -= run
+		gantt = append(gantt, TimeSlice{PID: p.ProcessID, Start: start, Stop: clock})
+
+		admitArrivals()
+
+		if remaining[p.ProcessID] == 0 {
+			exit[p.ProcessID] = clock
+			finalLevel[p.ProcessID] = level
+			completed++
+			turnaround := clock - p.ArrivalTime
+			sink.Complete(clock, p.ProcessID, turnaround-p.BurstDuration, turnaround)
+			continue
+		}
+
+		nextLevel := level
+		if level < len(queues)-1 {
+			nextLevel = level + 1
+		}
+		finalLevel[p.ProcessID] = nextLevel
+		queues[nextLevel] = append(queues[nextLevel], p)
+		sink.Preempt(clock, p.ProcessID)
+	}
+	gantt = coalesceGantt(gantt)
+
+	var (
+		totalWait       float64
+		totalTurnaround float64
+		results         = make([]ProcessResult, len(procs))
+		waits           = make([]float64, len(procs))
+		turnarounds     = make([]float64, len(procs))
+		responses       = make([]float64, len(procs))
+	)
+
+	for i := range procs {
+		turnaround := exit[procs[i].ProcessID] - procs[i].ArrivalTime
+		wait := turnaround - procs[i].BurstDuration
+		totalWait += float64(wait)
+		totalTurnaround += float64(turnaround)
+
+		results[i] = ProcessResult{
+			ProcessID:     procs[i].ProcessID,
+			Priority:      procs[i].Priority,
+			BurstDuration: procs[i].BurstDuration,
+			ArrivalTime:   procs[i].ArrivalTime,
+			Wait:          wait,
+			Turnaround:    turnaround,
+			Exit:          exit[procs[i].ProcessID],
+			Queue:         finalLevel[procs[i].ProcessID],
+		}
+
+		waits[i] = float64(wait)
+		turnarounds[i] = float64(turnaround)
+		responses[i] = float64(firstStart[procs[i].ProcessID] - procs[i].ArrivalTime)
+	}
+
+	count := float64(len(procs))
+	aveWait := totalWait / count
+	aveTurnaround := totalTurnaround / count
+	aveThroughput := count / float64(clock)
+
+	result := ScheduleResult{
+		Title:             title,
+		Gantt:             gantt,
+		Processes:         results,
+		AverageWait:       aveWait,
+		AverageTurnaround: aveTurnaround,
+		Throughput:        aveThroughput,
+	}
+	renderSchedule(w, format, result, newLatencyDigests(waits, turnarounds, responses), true)
+	return result
+}
+
+//endregion
+
+//region Percentile metrics (t-digest)
+
+// tdigestDelta controls the centroid size bound k(q) = n * q * (1-q) / delta; larger values
+// shrink the bound and keep more, finer centroids, smaller values merge into fewer, coarser ones.
+const tdigestDelta = 100.0
+
+// tdigestCentroid is a single (mean, weight) summary point in a TDigest.
+type tdigestCentroid struct {
+	mean   float64
+	weight float64
+}
+
+// TDigest is a streaming, bounded-memory approximation of a distribution's quantiles. It
+// merges each new sample into its closest centroid as long as that centroid's weight stays
+// under the size bound k(q); otherwise the sample becomes a new centroid. This keeps the
+// number of centroids small even over tens of thousands of samples, which matters for large
+// simulated workloads where sorting every sample would be wasteful.
+type TDigest struct {
+	centroids []tdigestCentroid
+	count     float64
+}
+
+// NewTDigest returns an empty TDigest.
+func NewTDigest() *TDigest {
+	return &TDigest{}
+}
+
+// Add inserts a single sample with weight 1.
+func (td *TDigest) Add(x float64) {
+	td.count++
+	if len(td.centroids) == 0 {
+		td.centroids = append(td.centroids, tdigestCentroid{mean: x, weight: 1})
+		return
+	}
+
+	closest, cumBefore := td.closestCentroid(x)
+
+	q := (cumBefore + td.centroids[closest].weight/2) / td.count
+	bound := td.count * q * (1 - q) / tdigestDelta
+
+	if td.centroids[closest].weight+1 <= bound {
+		c := &td.centroids[closest]
+		c.mean = (c.mean*c.weight + x) / (c.weight + 1)
+		c.weight++
+		return
+	}
+
+	td.centroids = append(td.centroids, tdigestCentroid{mean: x, weight: 1})
+	sort.Slice(td.centroids, func(i, j int) bool { return td.centroids[i].mean < td.centroids[j].mean })
+}
+
+// closestCentroid returns the index of the centroid nearest to x and the total weight of all
+// centroids before it.
+func (td *TDigest) closestCentroid(x float64) (idx int, cumBefore float64) {
+	bestDist := math.Inf(1)
+	var cum float64
+	for i, c := range td.centroids {
+		if d := math.Abs(c.mean - x); d < bestDist {
+			bestDist = d
+			idx = i
+			cumBefore = cum
+		}
+		cum += c.weight
+	}
+	return idx, cumBefore
+}
+
+// Quantile returns the approximate value at quantile q (0..1), linearly interpolating between
+// the means of the two centroids that straddle it.
+func (td *TDigest) Quantile(q float64) float64 {
+	if len(td.centroids) == 0 {
+		return 0
+	}
+	if len(td.centroids) == 1 {
+		return td.centroids[0].mean
+	}
+
+	target := q * td.count
+	var cum float64
+	for i, c := range td.centroids {
+		if cum+c.weight >= target {
+			if i == 0 {
+				return c.mean
+			}
+			prev := td.centroids[i-1]
+			frac := (target - cum) / c.weight
+			return prev.mean + frac*(c.mean-prev.mean)
+		}
+		cum += c.weight
+	}
+	return td.centroids[len(td.centroids)-1].mean
+}
+
+// latencyDigests bundles the wait/turnaround/response-time t-digests a scheduler reports
+// alongside its averages.
+type latencyDigests struct {
+	Wait       *TDigest
+	Turnaround *TDigest
+	Response   *TDigest
+}
+
+// newLatencyDigests builds a latencyDigests from parallel per-process samples.
+func newLatencyDigests(waits, turnarounds, responses []float64) latencyDigests {
+	ld := latencyDigests{Wait: NewTDigest(), Turnaround: NewTDigest(), Response: NewTDigest()}
+	for _, v := range waits {
+		ld.Wait.Add(v)
+	}
+	for _, v := range turnarounds {
+		ld.Turnaround.Add(v)
+	}
+	for _, v := range responses {
+		ld.Response.Add(v)
+	}
+	return ld
 }
 
 //endregion
@@ -423,7 +1398,7 @@ func outputGantt(w io.Writer, gantt []TimeSlice) {
 	_, _ = fmt.Fprintf(w, "\n\n")
 }
 
-func outputSchedule(w io.Writer, rows [][]string, wait, turnaround, throughput float64) {
+func outputSchedule(w io.Writer, rows [][]string, wait, turnaround, throughput float64, latency latencyDigests) {
 	_, _ = fmt.Fprintln(w, "Schedule table")
 	table := tablewriter.NewWriter(w)
 	table.SetHeader([]string{"ID", "Priority", "Burst", "Arrival", "Wait", "Turnaround", "Exit"})
@@ -433,6 +1408,46 @@ func outputSchedule(w io.Writer, rows [][]string, wait, turnaround, throughput f
 		fmt.Sprintf("Average\n%.2f", turnaround),
 		fmt.Sprintf("Throughput\n%.2f/t", throughput)})
 	table.Render()
+
+	outputPercentiles(w, latency)
+}
+
+// outputMLFQSchedule is outputSchedule plus a Queue column showing the final MLFQ priority
+// level each process ended in.
+func outputMLFQSchedule(w io.Writer, rows [][]string, wait, turnaround, throughput float64, latency latencyDigests) {
+	_, _ = fmt.Fprintln(w, "Schedule table")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"ID", "Priority", "Burst", "Arrival", "Wait", "Turnaround", "Exit", "Queue"})
+	table.AppendBulk(rows)
+	table.SetFooter([]string{"", "", "", "",
+		fmt.Sprintf("Average\n%.2f", wait),
+		fmt.Sprintf("Average\n%.2f", turnaround),
+		fmt.Sprintf("Throughput\n%.2f/t", throughput), ""})
+	table.Render()
+
+	outputPercentiles(w, latency)
+}
+
+// outputPercentiles renders p50/p90/p99 for wait, turnaround, and response time, computed from
+// t-digests so it stays cheap even for large simulated workloads.
+func outputPercentiles(w io.Writer, latency latencyDigests) {
+	_, _ = fmt.Fprintln(w, "Percentiles (p50 / p90 / p99)")
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Metric", "p50", "p90", "p99"})
+	table.Append(percentileRow("Wait", latency.Wait))
+	table.Append(percentileRow("Turnaround", latency.Turnaround))
+	table.Append(percentileRow("Response", latency.Response))
+	table.Render()
+	_, _ = fmt.Fprintln(w)
+}
+
+func percentileRow(metric string, td *TDigest) []string {
+	return []string{
+		metric,
+		fmt.Sprintf("%.2f", td.Quantile(0.5)),
+		fmt.Sprintf("%.2f", td.Quantile(0.9)),
+		fmt.Sprintf("%.2f", td.Quantile(0.99)),
+	}
 }
 
 //endregion
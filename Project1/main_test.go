@@ -0,0 +1,376 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestTDigestPercentiles guards against the centroid-merge bound collapsing to a single
+// centroid, which would make every quantile report the same value as the mean.
+func TestTDigestPercentiles(t *testing.T) {
+	td := NewTDigest()
+	for i := 1; i <= 1000; i++ {
+		td.Add(float64(i))
+	}
+
+	p50 := td.Quantile(0.5)
+	p90 := td.Quantile(0.9)
+	p99 := td.Quantile(0.99)
+
+	if p50 == p90 || p90 == p99 {
+		t.Fatalf("expected distinct percentiles, got p50=%v p90=%v p99=%v", p50, p90, p99)
+	}
+
+	const tolerance = 25.0
+	if want := 500.0; abs(p50-want) > tolerance {
+		t.Errorf("p50 = %v, want ~%v", p50, want)
+	}
+	if want := 900.0; abs(p90-want) > tolerance {
+		t.Errorf("p90 = %v, want ~%v", p90, want)
+	}
+	if want := 990.0; abs(p99-want) > tolerance {
+		t.Errorf("p99 = %v, want ~%v", p99, want)
+	}
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// TestSRTFScheduleGanttIdleGap guards against a process's Gantt slice being extended through a
+// CPU-idle gap: P1 finishes at t=3 and P2 doesn't arrive until t=5, so the schedule must show
+// two slices with a gap between them, not one slice running straight through the idle time.
+func TestSRTFScheduleGanttIdleGap(t *testing.T) {
+	processes := []Process{
+		{ProcessID: 1, BurstDuration: 3, ArrivalTime: 0, Priority: 1},
+		{ProcessID: 2, BurstDuration: 2, ArrivalTime: 5, Priority: 1},
+	}
+
+	result := SRTFSchedule(io.Discard, "test", processes, "table", nullSink{})
+
+	want := []TimeSlice{
+		{PID: 1, Start: 0, Stop: 3},
+		{PID: 2, Start: 5, Stop: 7},
+	}
+	if len(result.Gantt) != len(want) {
+		t.Fatalf("gantt = %+v, want %+v", result.Gantt, want)
+	}
+	for i, ts := range result.Gantt {
+		if ts != want[i] {
+			t.Errorf("gantt[%d] = %+v, want %+v", i, ts, want[i])
+		}
+	}
+}
+
+// TestSRTFSchedulePreempts checks that a shorter process arriving mid-burst preempts the one
+// currently running, rather than waiting for it to finish.
+func TestSRTFSchedulePreempts(t *testing.T) {
+	processes := []Process{
+		{ProcessID: 1, BurstDuration: 10, ArrivalTime: 0, Priority: 1},
+		{ProcessID: 2, BurstDuration: 3, ArrivalTime: 2, Priority: 1},
+	}
+
+	result := SRTFSchedule(io.Discard, "test", processes, "table", nullSink{})
+
+	if len(result.Gantt) < 2 || result.Gantt[0].PID != 1 || result.Gantt[0].Stop != 2 {
+		t.Fatalf("expected P1 to be preempted at t=2, got gantt %+v", result.Gantt)
+	}
+	if result.Gantt[1].PID != 2 || result.Gantt[1].Start != 2 {
+		t.Fatalf("expected P2 to take over at t=2, got gantt %+v", result.Gantt)
+	}
+}
+
+// TestSRTFScheduleTieBreak checks the documented tie-break order for equal remaining time:
+// lower Priority first, then lower ProcessID.
+func TestSRTFScheduleTieBreak(t *testing.T) {
+	processes := []Process{
+		{ProcessID: 2, BurstDuration: 5, ArrivalTime: 0, Priority: 2},
+		{ProcessID: 1, BurstDuration: 5, ArrivalTime: 0, Priority: 1},
+	}
+
+	result := SRTFSchedule(io.Discard, "test", processes, "table", nullSink{})
+
+	if len(result.Gantt) == 0 || result.Gantt[0].PID != 1 {
+		t.Fatalf("expected process with lower priority (P1) to run first, got gantt %+v", result.Gantt)
+	}
+}
+
+// TestMLFQScheduleDemotesOnFullQuantum checks that a process using its whole quantum at every
+// level is demoted all the way to the bottom queue when boosting is disabled.
+func TestMLFQScheduleDemotesOnFullQuantum(t *testing.T) {
+	processes := []Process{{ProcessID: 1, BurstDuration: 1000, ArrivalTime: 0, Priority: 1}}
+	quanta := []int64{2, 4, 8}
+
+	mlfqBoostInterval = 0
+	result := MLFQSchedule(io.Discard, "test", processes, quanta, "table", nullSink{})
+
+	if got, want := result.Processes[0].Queue, len(quanta)-1; got != want {
+		t.Errorf("final queue = %d, want %d (bottom level, never boosted)", got, want)
+	}
+}
+
+// TestMLFQScheduleBoostsAcrossQuantumJumps guards against the boost check missing ticks it
+// doesn't land on exactly: clock advances in level-sized jumps, so a boost due mid-quantum must
+// still fire. Without the fix, a long process demoted to the bottom queue stays there forever
+// even with boosting enabled.
+func TestMLFQScheduleBoostsAcrossQuantumJumps(t *testing.T) {
+	processes := []Process{
+		{ProcessID: 1, BurstDuration: 1000, ArrivalTime: 0, Priority: 1},
+		{ProcessID: 2, BurstDuration: 1, ArrivalTime: 500, Priority: 1},
+	}
+	quanta := []int64{2, 4, 8}
+
+	mlfqBoostInterval = 16
+	result := MLFQSchedule(io.Discard, "test", processes, quanta, "table", nullSink{})
+	mlfqBoostInterval = 0
+
+	if got, want := result.Processes[0].Queue, len(quanta)-1; got == want {
+		t.Errorf("final queue = %d, want less than %d: periodic boosting should have pulled P1 back out of the bottom queue", got, want)
+	}
+}
+
+// TestFCFSScheduleJSONFormat checks that "json" format renders the full ScheduleResult as a
+// single JSON object on w, instead of the table output.
+func TestFCFSScheduleJSONFormat(t *testing.T) {
+	processes := []Process{
+		{ProcessID: 1, BurstDuration: 4, ArrivalTime: 0, Priority: 1},
+		{ProcessID: 2, BurstDuration: 2, ArrivalTime: 1, Priority: 1},
+	}
+
+	var buf bytes.Buffer
+	want := FCFSSchedule(io.Discard, "test", processes, "json", nullSink{})
+
+	FCFSSchedule(&buf, "test", processes, "json", nullSink{})
+
+	var got ScheduleResult
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json output did not parse as ScheduleResult: %v\noutput: %s", err, buf.String())
+	}
+	if len(got.Processes) != len(want.Processes) {
+		t.Fatalf("got %d processes, want %d", len(got.Processes), len(want.Processes))
+	}
+	if got.AverageWait != want.AverageWait {
+		t.Errorf("average_wait = %v, want %v", got.AverageWait, want.AverageWait)
+	}
+}
+
+// recordingSink counts the events delivered through an EventSink, so a test can check that a
+// scheduler actually drives dispatch/complete events instead of just returning a result.
+type recordingSink struct {
+	dispatches int
+	completes  int
+}
+
+func (s *recordingSink) Dispatch(int64, int64)               { s.dispatches++ }
+func (s *recordingSink) Preempt(int64, int64)                {}
+func (s *recordingSink) Complete(int64, int64, int64, int64) { s.completes++ }
+
+// TestFCFSScheduleDrivesEventSink checks that every process produces a Dispatch and a Complete
+// event on the sink passed in, regardless of the output format.
+func TestFCFSScheduleDrivesEventSink(t *testing.T) {
+	processes := []Process{
+		{ProcessID: 1, BurstDuration: 4, ArrivalTime: 0, Priority: 1},
+		{ProcessID: 2, BurstDuration: 2, ArrivalTime: 1, Priority: 1},
+	}
+
+	sink := &recordingSink{}
+	FCFSSchedule(io.Discard, "test", processes, "table", sink)
+
+	if sink.dispatches != len(processes) {
+		t.Errorf("dispatches = %d, want %d", sink.dispatches, len(processes))
+	}
+	if sink.completes != len(processes) {
+		t.Errorf("completes = %d, want %d", sink.completes, len(processes))
+	}
+}
+
+// TestNdjsonSinkEmitsOneObjectPerEvent checks that ndjsonSink writes one JSON object per line,
+// with the event name and fields renderSchedule's ndjson branch relies on being already streamed.
+func TestNdjsonSinkEmitsOneObjectPerEvent(t *testing.T) {
+	var buf bytes.Buffer
+	sink := ndjsonSink{w: &buf}
+
+	sink.Dispatch(0, 1)
+	sink.Complete(4, 1, 0, 4)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+
+	var dispatch, complete map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &dispatch); err != nil {
+		t.Fatalf("dispatch line did not parse: %v", err)
+	}
+	if dispatch["event"] != "dispatch" {
+		t.Errorf("dispatch event = %v, want dispatch", dispatch["event"])
+	}
+
+	if err := json.Unmarshal([]byte(lines[1]), &complete); err != nil {
+		t.Fatalf("complete line did not parse: %v", err)
+	}
+	if complete["event"] != "complete" {
+		t.Errorf("complete event = %v, want complete", complete["event"])
+	}
+	if complete["turnaround"] != float64(4) {
+		t.Errorf("complete turnaround = %v, want 4", complete["turnaround"])
+	}
+}
+
+// TestGenerateWorkloadDeterministic checks that the same seed produces the same workload, since
+// the simulate subcommand's output must be reproducible for -dump/replay to make sense.
+func TestGenerateWorkloadDeterministic(t *testing.T) {
+	a := generateWorkload(50, 1.0, 1.0, 4, 42)
+	b := generateWorkload(50, 1.0, 1.0, 4, 42)
+
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("two runs with the same seed produced different workloads:\n%+v\n%+v", a, b)
+	}
+}
+
+// TestGenerateWorkloadInvariants checks the properties generateWorkload's doc comment promises:
+// arrivals are non-decreasing and every burst duration is at least 1.
+func TestGenerateWorkloadInvariants(t *testing.T) {
+	processes := generateWorkload(200, 2.0, 1.5, 8, 7)
+
+	if len(processes) != 200 {
+		t.Fatalf("got %d processes, want 200", len(processes))
+	}
+
+	var prevArrival int64
+	for i, p := range processes {
+		if p.ArrivalTime < prevArrival {
+			t.Errorf("process %d: arrival %d is before previous arrival %d", i, p.ArrivalTime, prevArrival)
+		}
+		prevArrival = p.ArrivalTime
+
+		if p.BurstDuration < 1 {
+			t.Errorf("process %d: burst duration %d, want >= 1", i, p.BurstDuration)
+		}
+	}
+}
+
+// TestDumpProcessesCSVRoundTrip checks that dumping a workload and loading it back with
+// loadProcesses reproduces the same processes, so a simulated run can be replayed exactly.
+func TestDumpProcessesCSVRoundTrip(t *testing.T) {
+	processes := generateWorkload(20, 1.0, 1.0, 4, 99)
+
+	path := filepath.Join(t.TempDir(), "workload.csv")
+	if err := dumpProcessesCSV(path, processes); err != nil {
+		t.Fatalf("dumpProcessesCSV: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening dumped file: %v", err)
+	}
+	defer f.Close()
+
+	got, err := loadProcesses(f)
+	if err != nil {
+		t.Fatalf("loadProcesses: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, processes) {
+		t.Fatalf("round-tripped processes differ:\ngot:  %+v\nwant: %+v", got, processes)
+	}
+}
+
+// TestParseAlgosDefaultsToAll checks that both "" and "all" expand to every built-in scheduler,
+// in allAlgos order.
+func TestParseAlgosDefaultsToAll(t *testing.T) {
+	for _, s := range []string{"", "all"} {
+		got, err := parseAlgos(s)
+		if err != nil {
+			t.Fatalf("parseAlgos(%q): %v", s, err)
+		}
+		if !reflect.DeepEqual(got, allAlgos) {
+			t.Errorf("parseAlgos(%q) = %v, want %v", s, got, allAlgos)
+		}
+	}
+}
+
+// TestParseAlgosSubset checks that a comma-separated subset is parsed in the order given, with
+// whitespace trimmed.
+func TestParseAlgosSubset(t *testing.T) {
+	got, err := parseAlgos("sjf, fcfs")
+	if err != nil {
+		t.Fatalf("parseAlgos: %v", err)
+	}
+	want := []string{"sjf", "fcfs"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseAlgos(\"sjf, fcfs\") = %v, want %v", got, want)
+	}
+}
+
+// TestParseAlgosRejectsUnknown checks that an unrecognized scheduler name is rejected instead of
+// silently passed through to the registry lookup.
+func TestParseAlgosRejectsUnknown(t *testing.T) {
+	if _, err := parseAlgos("fcfs,bogus"); err == nil {
+		t.Fatal("expected an error for an unknown -algo name, got nil")
+	}
+}
+
+// TestRegisterBuiltinsPopulatesAllAlgos checks that registerBuiltins registers a Scheduler under
+// every name in allAlgos.
+func TestRegisterBuiltinsPopulatesAllAlgos(t *testing.T) {
+	registerBuiltins("table", nullSink{}, []int64{2, 4, 8}, 2)
+
+	for _, name := range allAlgos {
+		s, ok := registry[name]
+		if !ok {
+			t.Errorf("registry missing scheduler %q", name)
+			continue
+		}
+		if s.Name() != name {
+			t.Errorf("registry[%q].Name() = %q, want %q", name, s.Name(), name)
+		}
+	}
+}
+
+// TestClonedProcessesIsIndependent checks that clonedProcesses returns a copy that sorting (or
+// any other in-place mutation) doesn't feed back into the original slice.
+func TestClonedProcessesIsIndependent(t *testing.T) {
+	original := []Process{
+		{ProcessID: 1, BurstDuration: 5},
+		{ProcessID: 2, BurstDuration: 1},
+	}
+
+	cloned := clonedProcesses(original)
+	cloned[0], cloned[1] = cloned[1], cloned[0]
+
+	if original[0].ProcessID != 1 || original[1].ProcessID != 2 {
+		t.Fatalf("mutating the clone affected the original: %+v", original)
+	}
+}
+
+// TestRegistryAlgoRunIsIndependentAcrossSchedulers is a regression test for the registry cross-
+// mutation bug: running sjf (which sorts its input) before fcfs over the same source slice must
+// not change fcfs's result, since each run gets its own cloned slice.
+func TestRegistryAlgoRunIsIndependentAcrossSchedulers(t *testing.T) {
+	processes := []Process{
+		{ProcessID: 1, BurstDuration: 5, ArrivalTime: 0, Priority: 1},
+		{ProcessID: 2, BurstDuration: 1, ArrivalTime: 0, Priority: 1},
+		{ProcessID: 3, BurstDuration: 3, ArrivalTime: 0, Priority: 1},
+	}
+
+	registerBuiltins("table", nullSink{}, []int64{2, 4, 8}, 2)
+
+	independentFCFS := registry["fcfs"].Run(io.Discard, clonedProcesses(processes))
+
+	registry["sjf"].Run(io.Discard, clonedProcesses(processes))
+	afterSJF := registry["fcfs"].Run(io.Discard, clonedProcesses(processes))
+
+	if !reflect.DeepEqual(independentFCFS, afterSJF) {
+		t.Fatalf("fcfs result changed after sjf ran over the same source slice:\nbefore: %+v\nafter:  %+v", independentFCFS, afterSJF)
+	}
+}